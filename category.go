@@ -0,0 +1,54 @@
+package main
+
+import "fmt"
+
+// itunesCategories is Apple's fixed vocabulary of <itunes:category> values
+// and the subcategories each one permits. An empty category is allowed
+// (validateItunesCategory treats it as "not set"), but a non-empty one must
+// be an exact match, since Apple Podcasts ignores feeds with unrecognised
+// categories rather than merely warning about them.
+//
+// This list is deliberately not exhaustive of every subcategory Apple has
+// ever published; it covers the categories this project's podcasts
+// actually use. Extend it as needed.
+var itunesCategories = map[string][]string{
+	"Arts":              {"Books", "Design", "Fashion & Beauty", "Food", "Performing Arts", "Visual Arts"},
+	"Business":          {"Careers", "Entrepreneurship", "Investing", "Management", "Marketing", "Non-Profit"},
+	"Comedy":            {"Comedy Interviews", "Improv", "Stand-Up"},
+	"Education":         {"Courses", "How To", "Language Learning", "Self-Improvement"},
+	"Games & Hobbies":   {"Automotive", "Aviation", "Hobbies", "Other Games", "Video Games"},
+	"Health & Fitness":  {"Alternative Health", "Fitness", "Medicine", "Mental Health", "Nutrition", "Sexuality"},
+	"History":           nil,
+	"Leisure":           {"Animation & Manga", "Automotive", "Aviation", "Crafts", "Games", "Hobbies", "Home & Garden", "Video Games"},
+	"Music":             {"Music Commentary", "Music History", "Music Interviews"},
+	"News":              {"Business News", "Daily News", "Entertainment News", "News Commentary", "Politics", "Sports News", "Tech News"},
+	"Science":           {"Astronomy", "Chemistry", "Earth Sciences", "Life Sciences", "Mathematics", "Natural Sciences", "Nature", "Physics", "Social Sciences"},
+	"Society & Culture": {"Documentary", "Personal Journals", "Philosophy", "Places & Travel", "Relationships"},
+	"Sports":            {"Baseball", "Basketball", "Cricket", "Fantasy Sports", "Football", "Golf", "Hockey", "Rugby", "Running", "Soccer", "Swimming", "Tennis", "Volleyball", "Wilderness", "Wrestling"},
+	"Technology":        nil,
+	"TV & Film":         {"After Shows", "Film History", "Film Interviews", "Film Reviews", "TV Reviews"},
+	"True Crime":        nil,
+}
+
+func validateItunesCategory(category, subcategory string) error {
+	if category == "" {
+		if subcategory != "" {
+			return fmt.Errorf("itunes_subcategory %q set without itunes_category", subcategory)
+		}
+		return nil
+	}
+
+	subs, ok := itunesCategories[category]
+	if !ok {
+		return fmt.Errorf("itunes_category %q is not one of Apple Podcasts' categories", category)
+	}
+	if subcategory == "" {
+		return nil
+	}
+	for _, s := range subs {
+		if s == subcategory {
+			return nil
+		}
+	}
+	return fmt.Errorf("itunes_subcategory %q is not valid under category %q", subcategory, category)
+}
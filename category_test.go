@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestValidateItunesCategory(t *testing.T) {
+	cases := []struct {
+		name, category, subcategory string
+		wantErr                     bool
+	}{
+		{"both empty", "", "", false},
+		{"subcategory without category", "", "Improv", true},
+		{"unknown category", "Not A Real Category", "", true},
+		{"category with no subcategory given", "Comedy", "", false},
+		{"valid category and subcategory", "Comedy", "Improv", false},
+		{"subcategory not valid under category", "Comedy", "Fitness", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateItunesCategory(c.category, c.subcategory)
+			if (err != nil) != c.wantErr {
+				t.Errorf("validateItunesCategory(%q, %q) error = %v, wantErr %v",
+					c.category, c.subcategory, err, c.wantErr)
+			}
+		})
+	}
+}
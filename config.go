@@ -15,7 +15,8 @@ import (
 )
 
 type config struct {
-	YTDataAPIKey string `json:"yt_data_api_key"`
+	YTDataAPIKey    string `json:"yt_data_api_key"`
+	YTAPIQuotaDaily int    `json:"yt_api_quota_daily"`
 	watcherConfig
 	Podcasts []podcast `json:"podcasts"`
 }
@@ -38,6 +39,9 @@ type watcherConfig struct {
 	ServeHost              string `json:"serve_host"`
 	ServePort              int    `json:"serve_port"`
 	ServeDirectoryListings bool   `json:"serve_directory_listings"`
+
+	DownloadMaxAttempts         int `json:"download_max_attempts"`
+	DownloadRetryBackoffSeconds int `json:"download_retry_backoff_seconds"`
 }
 
 func (wc *watcherConfig) urlFor(filePath string) string {
@@ -51,9 +55,7 @@ func (wc *watcherConfig) urlFor(filePath string) string {
 // ------------------------------------------------------------
 
 type podcast struct {
-	YTChannel             string `json:"yt_channel"`
-	YTChannelID           string
-	YTChannelReadableName string
+	Source Source `json:"-"`
 
 	Name        string `json:"name"`
 	ShortName   string `json:"short_name"`
@@ -67,6 +69,78 @@ type podcast struct {
 
 	Vidya           bool   `json:"vidya"`
 	CustomImagePath string `json:"custom_image"`
+
+	ItunesCategory    string `json:"itunes_category"`
+	ItunesSubcategory string `json:"itunes_subcategory"`
+	ItunesExplicit    bool   `json:"itunes_explicit"`
+	ItunesAuthor      string `json:"itunes_author"`
+	ItunesOwnerEmail  string `json:"itunes_owner_email"`
+	ItunesType        string `json:"itunes_type"` // "episodic" or "serial"
+	ItunesComplete    bool   `json:"itunes_complete"`
+
+	// ExplicitFilterStr, if set, overrides ItunesExplicit on a per-episode
+	// basis: an episode whose title or description matches is flagged
+	// explicit regardless of the podcast-level default.
+	ExplicitFilterStr string `json:"explicit_filter"`
+	ExplicitFilter    *regexp.Regexp
+
+	// TrailerFilterStr and BonusFilterStr, if set, flag an episode's
+	// itunes:episodeType as "trailer" or "bonus" (instead of the default
+	// "full") when its title or description matches. TrailerFilter is
+	// checked first, so an episode matching both is a trailer.
+	TrailerFilterStr string `json:"trailer_filter"`
+	TrailerFilter    *regexp.Regexp
+	BonusFilterStr   string `json:"bonus_filter"`
+	BonusFilter      *regexp.Regexp
+
+	// SeasonEpisodeFilterStr, if set, is matched against an episode's title
+	// to populate itunes:season/itunes:episode. It must use the named
+	// capture groups "season" and "episode"; either may be omitted from a
+	// match (e.g. to number episodes without seasons).
+	SeasonEpisodeFilterStr string `json:"season_episode_filter"`
+	SeasonEpisodeFilter    *regexp.Regexp
+
+	IncludeLivestreams           bool `json:"include_livestreams"`
+	LivestreamMinFinishedMinutes int  `json:"livestream_min_finished_minutes"`
+	DropWhenUnlisted             bool `json:"drop_when_unlisted"`
+
+	// Language is a BCP-47 tag (e.g. "en-US", "ja") for the feed as a
+	// whole. LanguageFilterStr, if set, skips videos whose detected
+	// language doesn't match it, so the same source can be split into
+	// several single-language feeds.
+	Language          string `json:"language"`
+	LanguageFilterStr string `json:"language_filter"`
+}
+
+// UnmarshalJSON decodes a podcast's "source" object, dispatching on its
+// "type" field. For backward compatibility, a bare "yt_channel" string (the
+// only form this field used to take) is treated as {"type":"channel"}.
+func (p *podcast) UnmarshalJSON(data []byte) error {
+	type podcastAlias podcast
+	aux := struct {
+		Source    json.RawMessage `json:"source"`
+		YTChannel string          `json:"yt_channel"`
+		*podcastAlias
+	}{
+		podcastAlias: (*podcastAlias)(p),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch {
+	case len(aux.Source) > 0:
+		src, err := unmarshalSource(aux.Source)
+		if err != nil {
+			return err
+		}
+		p.Source = src
+	case aux.YTChannel != "":
+		p.Source = &channelSource{Value: aux.YTChannel}
+	default:
+		return errors.New("podcast has neither \"source\" nor (deprecated) \"yt_channel\"")
+	}
+	return nil
 }
 
 func (p *podcast) feedPath() string {
@@ -119,6 +193,13 @@ func loadConfig(path string) (c *config, err error) {
 	// Normalize e.g. ".m4a" and "m4a"
 	c.YTDLWriteExt = strings.TrimLeft(c.YTDLWriteExt, ".")
 
+	if c.DownloadMaxAttempts <= 0 {
+		c.DownloadMaxAttempts = 1
+	}
+	if c.DownloadRetryBackoffSeconds <= 0 {
+		c.DownloadRetryBackoffSeconds = 30
+	}
+
 	var podcastShortNameSet set.Strings
 	for i := range c.Podcasts {
 		// Parse Epoch
@@ -141,6 +222,55 @@ func loadConfig(path string) (c *config, err error) {
 		}
 		c.Podcasts[i].TitleFilter = re
 
+		// Parse the optional per-episode explicit-flag override filter.
+		if efs := c.Podcasts[i].ExplicitFilterStr; efs != "" {
+			re, err := regexp.Compile(fmt.Sprintf("(?i:%s)", efs))
+			if err != nil {
+				return nil, err
+			}
+			c.Podcasts[i].ExplicitFilter = re
+		}
+
+		// Parse the optional per-episode trailer/bonus type-override filters.
+		if tfs := c.Podcasts[i].TrailerFilterStr; tfs != "" {
+			re, err := regexp.Compile(fmt.Sprintf("(?i:%s)", tfs))
+			if err != nil {
+				return nil, err
+			}
+			c.Podcasts[i].TrailerFilter = re
+		}
+		if bfs := c.Podcasts[i].BonusFilterStr; bfs != "" {
+			re, err := regexp.Compile(fmt.Sprintf("(?i:%s)", bfs))
+			if err != nil {
+				return nil, err
+			}
+			c.Podcasts[i].BonusFilter = re
+		}
+
+		// Parse the optional season/episode-number filter.
+		if sefs := c.Podcasts[i].SeasonEpisodeFilterStr; sefs != "" {
+			re, err := regexp.Compile(fmt.Sprintf("(?i:%s)", sefs))
+			if err != nil {
+				return nil, err
+			}
+			c.Podcasts[i].SeasonEpisodeFilter = re
+		}
+
+		// Validate the iTunes category/subcategory against Apple's fixed
+		// vocabulary, and the podcast type against the two values RSS
+		// readers understand.
+		if err := validateItunesCategory(
+			c.Podcasts[i].ItunesCategory, c.Podcasts[i].ItunesSubcategory); err != nil {
+			return nil, fmt.Errorf("podcast %q: %w", c.Podcasts[i].ShortName, err)
+		}
+		switch t := c.Podcasts[i].ItunesType; t {
+		case "", "episodic", "serial":
+		default:
+			return nil, fmt.Errorf(
+				"podcast %q: itunes_type must be \"episodic\" or \"serial\", not %q",
+				c.Podcasts[i].ShortName, t)
+		}
+
 		// Check for podcast shortname (in effect primary key) collisions.
 		sn := c.Podcasts[i].ShortName
 		// TODO: Check that shortname is not empty string either
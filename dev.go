@@ -0,0 +1,109 @@
+package main
+
+import (
+	"log"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// devSupervisor watches the config file (and the custom podcast art it
+// references) for changes and reconciles the running watchers against the
+// reloaded config, without dropping the HTTP server or any in-flight
+// download. Modelled on the dev-server rebuild loop used by hugo-website.
+type devSupervisor struct {
+	configPath string
+	mgr        *watcherManager
+	fsw        *fsnotify.Watcher
+}
+
+func newDevSupervisor(configPath string, mgr *watcherManager) (*devSupervisor, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &devSupervisor{
+		configPath: configPath,
+		mgr:        mgr,
+		fsw:        fsw,
+	}
+	if err := d.watchConfigAndArt(); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// watchConfigAndArt (re-)establishes fsnotify watches on the directory
+// holding the config file plus every custom_image path it currently
+// references. It is called
+// once at startup and again after every successful reload, since a reload
+// can add or remove custom image paths.
+func (d *devSupervisor) watchConfigAndArt() error {
+	if err := d.fsw.Add(filepath.Dir(d.configPath)); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(d.configPath)
+	if err != nil {
+		// The config may be mid-edit; fsnotify will tell us when it
+		// settles and we can try again.
+		return nil
+	}
+	for _, p := range cfg.Podcasts {
+		if p.CustomImagePath == "" {
+			continue
+		}
+		if err := d.fsw.Add(p.CustomImagePath); err != nil {
+			log.Printf("dev: watch %s: %v", p.CustomImagePath, err)
+		}
+	}
+	return nil
+}
+
+// run services fsnotify events until the watcher is closed. It never
+// returns in normal operation, so callers should invoke it in its own
+// goroutine.
+func (d *devSupervisor) run() {
+	log.Println("dev: watching", d.configPath, "for changes")
+	for {
+		select {
+		case ev, ok := <-d.fsw.Events:
+			if !ok {
+				return
+			}
+			if ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			d.reload()
+
+		case err, ok := <-d.fsw.Errors:
+			if !ok {
+				return
+			}
+			log.Println("dev: fsnotify:", err)
+		}
+	}
+}
+
+// reload re-reads the config file and brings the running watchers in line
+// with it: added podcasts are started, removed ones are stopped (their feed
+// and art files are preserved on disk), and existing ones are rebuilt in
+// place so a changed title_filter, epoch, description, or format selector
+// takes effect on the next tick without restarting the process.
+func (d *devSupervisor) reload() {
+	cfg, err := loadConfig(d.configPath)
+	if err != nil {
+		log.Println("dev: reload: invalid config, keeping previous:", err)
+		return
+	}
+	if err := d.mgr.sync(cfg); err != nil {
+		log.Println("dev: reload: sync:", err)
+		return
+	}
+	if err := d.watchConfigAndArt(); err != nil {
+		log.Println("dev: reload: rewatch:", err)
+	}
+	log.Println("dev: reloaded", d.configPath)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// permanentFailureMarkers is stderr substrings from yt-dlp that mean a
+// video will never successfully download, so retrying it is pointless.
+// Lifted from ytsync's hardcoded "never retry those" list.
+var permanentFailureMarkers = []string{
+	"Video unavailable",
+	"Private video",
+	"Members-only content",
+	"This video has been removed",
+	"Sign in to confirm your age",
+	"requested format not available",
+	"Premieres in",
+}
+
+func isPermanentFailure(stderr string) bool {
+	for _, m := range permanentFailureMarkers {
+		if strings.Contains(stderr, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadState is one video's download attempt history, persisted to disk
+// so a process restart doesn't reset its retry counter or forget that it
+// has already failed permanently.
+type downloadState struct {
+	Attempts       int       `json:"attempts"`
+	LastErrorClass string    `json:"last_error_class,omitempty"`
+	NextRetryAt    time.Time `json:"next_retry_at,omitempty"`
+	Permanent      bool      `json:"permanent"`
+}
+
+func (p *podcast) downloadStatePath() string {
+	return filepath.Join(dataSubdirMetadata, p.ShortName+".downloads.json")
+}
+
+func (p *podcast) loadDownloadStates() (map[string]downloadState, error) {
+	buf, err := ioutil.ReadFile(p.downloadStatePath())
+	if os.IsNotExist(err) {
+		return make(map[string]downloadState), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]downloadState)
+	if err := json.Unmarshal(buf, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (p *podcast) saveDownloadStates(states map[string]downloadState) error {
+	buf, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.downloadStatePath(), buf, 0644)
+}
+
+// downloadVideo runs yt-dlp for v at most once per call, so the backoff
+// computed after a transient failure actually elapses: downloadVideo is
+// called again on a later poll, rather than retried in a tight loop within
+// one call. It reports ok=false without attempting anything if v previously
+// failed permanently or is still in backoff from a prior attempt, and marks
+// v permanent once download_max_attempts is reached so it isn't silently
+// retried forever with no further log signal.
+func (w *watcher) downloadVideo(p podcast, wc watcherConfig, v videoToDownload) (ok bool, err error) {
+	states, err := p.loadDownloadStates()
+	if err != nil {
+		return false, err
+	}
+	state := states[v.ID]
+
+	if state.Permanent {
+		return false, nil
+	}
+	if !state.NextRetryAt.IsZero() && time.Now().Before(state.NextRetryAt) {
+		return false, nil
+	}
+
+	outPath := filepath.Join(dataSubdirMedia, v.ID+"."+wc.YTDLWriteExt)
+	state.Attempts++
+
+	cmd := exec.Command(downloadCmdName,
+		"-f", wc.YTDLFmtSelector,
+		"-o", outPath,
+		"https://www.youtube.com/watch?v="+v.ID)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	if runErr == nil {
+		delete(states, v.ID)
+		return true, p.saveDownloadStates(states)
+	}
+
+	attemptErr := fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), runErr)
+	state.LastErrorClass = classifyErrorMessage(stderr.String())
+
+	switch {
+	case isPermanentFailure(stderr.String()):
+		state.Permanent = true
+		logEvent(p.ShortName, v.ID, "download-permanent-failure",
+			"error", state.LastErrorClass)
+
+	case state.Attempts >= wc.DownloadMaxAttempts:
+		state.Permanent = true
+		logEvent(p.ShortName, v.ID, "download-attempts-exhausted",
+			"attempts", fmt.Sprintf("%d", state.Attempts), "error", state.LastErrorClass)
+
+	default:
+		state.NextRetryAt = time.Now().Add(backoffWithJitter(wc.DownloadRetryBackoffSeconds, state.Attempts))
+	}
+
+	states[v.ID] = state
+	if saveErr := p.saveDownloadStates(states); saveErr != nil {
+		return false, saveErr
+	}
+	return false, attemptErr
+}
+
+// classifyErrorMessage reduces a yt-dlp stderr blob to a short class label
+// for logging/persistence, rather than storing the whole (often multi-line)
+// message.
+func classifyErrorMessage(stderr string) string {
+	for _, m := range permanentFailureMarkers {
+		if strings.Contains(stderr, m) {
+			return m
+		}
+	}
+	if line := strings.SplitN(strings.TrimSpace(stderr), "\n", 2)[0]; line != "" {
+		return line
+	}
+	return "unknown error"
+}
+
+// backoffWithJitter computes an exponential backoff duration for the given
+// attempt number (1-indexed), with up to 50% jitter added to avoid thundering
+// herds when several videos fail at once.
+func backoffWithJitter(baseSeconds, attempt int) time.Duration {
+	backoff := time.Duration(baseSeconds) * time.Second * time.Duration(1<<uint(attempt-1))
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+	return backoff + jitter
+}
+
+// videoToDownload is the subset of fields downloadVideo needs, so it isn't
+// coupled to the ytapi package directly.
+type videoToDownload struct {
+	ID          string
+	Title       string
+	Description string
+	PublishedAt time.Time
+}
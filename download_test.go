@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsPermanentFailure(t *testing.T) {
+	cases := []struct {
+		stderr string
+		want   bool
+	}{
+		{"ERROR: [youtube] abc123: Private video. Sign in if you've been granted access", true},
+		{"ERROR: [youtube] abc123: Video unavailable", true},
+		{"ERROR: unable to download webpage: HTTP Error 503", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := isPermanentFailure(c.stderr); got != c.want {
+			t.Errorf("isPermanentFailure(%q) = %v, want %v", c.stderr, got, c.want)
+		}
+	}
+}
+
+func TestClassifyErrorMessage(t *testing.T) {
+	cases := []struct {
+		stderr string
+		want   string
+	}{
+		{"ERROR: [youtube] abc123: Private video", "Private video"},
+		{"ERROR: unable to download webpage\nmore detail on the next line", "ERROR: unable to download webpage"},
+		{"", "unknown error"},
+	}
+	for _, c := range cases {
+		if got := classifyErrorMessage(c.stderr); got != c.want {
+			t.Errorf("classifyErrorMessage(%q) = %q, want %q", c.stderr, got, c.want)
+		}
+	}
+}
+
+func TestBackoffWithJitterGrowsAndStaysBounded(t *testing.T) {
+	const base = 10
+	for attempt := 1; attempt <= 5; attempt++ {
+		backoff := time.Duration(base) * time.Second * time.Duration(1<<uint(attempt-1))
+		got := backoffWithJitter(base, attempt)
+		if got < backoff {
+			t.Errorf("backoffWithJitter(%d, %d) = %v, want >= %v", base, attempt, got, backoff)
+		}
+		if got >= backoff+backoff/2+time.Second {
+			t.Errorf("backoffWithJitter(%d, %d) = %v, jitter exceeded the documented 50%% bound", base, attempt, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// episode is one downloaded video belonging to a podcast: everything the
+// feed generator needs to emit its <item>, beyond what's in the podcast's
+// own config.
+type episode struct {
+	YTVideoID   string    `json:"yt_video_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+
+	MediaFilePath string `json:"media_file_path"`
+	MediaFileSize int64  `json:"media_file_size"`
+	DurationSecs  int    `json:"duration_secs"`
+
+	Season      int    `json:"season,omitempty"`
+	EpisodeNum  int    `json:"episode_num,omitempty"`
+	EpisodeType string `json:"episode_type"` // "full", "trailer", or "bonus"
+	Explicit    bool   `json:"explicit"`
+	Language    string `json:"language,omitempty"`
+
+	TranscriptPath string `json:"transcript_path,omitempty"`
+}
+
+func (p *podcast) episodesPath() string {
+	return filepath.Join(dataSubdirMetadata, p.ShortName+".episodes.json")
+}
+
+// loadEpisodes returns the episodes known to have been downloaded for p, in
+// the order they were recorded (oldest first). A podcast with no episodes
+// yet is not an error.
+func (p *podcast) loadEpisodes() ([]episode, error) {
+	buf, err := ioutil.ReadFile(p.episodesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var episodes []episode
+	if err := json.Unmarshal(buf, &episodes); err != nil {
+		return nil, err
+	}
+	return episodes, nil
+}
+
+// saveEpisodes persists the full set of known episodes for p.
+func (p *podcast) saveEpisodes(episodes []episode) error {
+	buf, err := json.MarshalIndent(episodes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.episodesPath(), buf, 0644)
+}
+
+// seasonAndEpisodeNumber extracts itunes:season/itunes:episode from title
+// using filter's "season"/"episode" named capture groups. Either value is
+// left at 0 (meaning "unset") if filter is nil, doesn't match, or the
+// corresponding group didn't match or isn't a valid integer.
+func seasonAndEpisodeNumber(filter *regexp.Regexp, title string) (season, episodeNum int) {
+	if filter == nil {
+		return 0, 0
+	}
+	match := filter.FindStringSubmatch(title)
+	if match == nil {
+		return 0, 0
+	}
+	for i, name := range filter.SubexpNames() {
+		switch name {
+		case "season":
+			season, _ = strconv.Atoi(match[i])
+		case "episode":
+			episodeNum, _ = strconv.Atoi(match[i])
+		}
+	}
+	return season, episodeNum
+}
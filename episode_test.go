@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestSeasonAndEpisodeNumber(t *testing.T) {
+	filter := regexp.MustCompile(`S(?P<season>\d+)E(?P<episode>\d+)`)
+
+	cases := []struct {
+		title          string
+		filter         *regexp.Regexp
+		wantSeason     int
+		wantEpisodeNum int
+	}{
+		{"Ep 12: S2E07 - The Big One", filter, 2, 7},
+		{"No season/episode markers here", filter, 0, 0},
+		{"S2E07", nil, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q", c.title), func(t *testing.T) {
+			season, episodeNum := seasonAndEpisodeNumber(c.filter, c.title)
+			if season != c.wantSeason || episodeNum != c.wantEpisodeNum {
+				t.Errorf("seasonAndEpisodeNumber(%v, %q) = (%d, %d), want (%d, %d)",
+					c.filter, c.title, season, episodeNum, c.wantSeason, c.wantEpisodeNum)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// podcastGUIDNamespace is the namespace UUID used to derive a stable
+// <podcast:guid> from a feed's URL, per the Podcasting 2.0 namespace spec.
+var podcastGUIDNamespace = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+// rssFeed is the root of a podcast RSS document, including the iTunes and
+// Podcasting 2.0 (podcastindex.org) extension namespaces. It replaces
+// the jbub/podcasts generator, which had no way to emit podcast:* tags.
+type rssFeed struct {
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	XmlnsItunes  string     `xml:"xmlns:itunes,attr"`
+	XmlnsPodcast string     `xml:"xmlns:podcast,attr"`
+	Channel      rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Language    string `xml:"language,omitempty"`
+
+	ItunesImage    *itunesHref     `xml:"itunes:image"`
+	ItunesCategory *itunesCategory `xml:"itunes:category"`
+	ItunesExplicit string          `xml:"itunes:explicit"`
+	ItunesAuthor   string          `xml:"itunes:author,omitempty"`
+	ItunesOwner    *itunesOwner    `xml:"itunes:owner"`
+	ItunesType     string          `xml:"itunes:type,omitempty"`
+	ItunesComplete string          `xml:"itunes:complete,omitempty"`
+	ItunesLanguage string          `xml:"itunes:language,omitempty"`
+
+	PodcastGUID string `xml:"podcast:guid,omitempty"`
+
+	Items []rssItem `xml:"item"`
+}
+
+type itunesHref struct {
+	Href string `xml:"href,attr"`
+}
+
+type itunesCategory struct {
+	Text        string          `xml:"text,attr"`
+	Subcategory *itunesCategory `xml:"itunes:category"`
+}
+
+type itunesOwner struct {
+	Name  string `xml:"itunes:name,omitempty"`
+	Email string `xml:"itunes:email,omitempty"`
+}
+
+type rssItem struct {
+	Title       string    `xml:"title"`
+	Description string    `xml:"description"`
+	PubDate     string    `xml:"pubDate"`
+	GUID        string    `xml:"guid"`
+	Enclosure   enclosure `xml:"enclosure"`
+
+	ItunesDuration    string `xml:"itunes:duration,omitempty"`
+	ItunesExplicit    string `xml:"itunes:explicit,omitempty"`
+	ItunesSeason      int    `xml:"itunes:season,omitempty"`
+	ItunesEpisode     int    `xml:"itunes:episode,omitempty"`
+	ItunesEpisodeType string `xml:"itunes:episodeType,omitempty"`
+	Language          string `xml:"language,omitempty"`
+
+	PodcastTranscript *podcastTranscript `xml:"podcast:transcript"`
+}
+
+type enclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type podcastTranscript struct {
+	URL  string `xml:"url,attr"`
+	Type string `xml:"type,attr"`
+}
+
+// generateFeed renders p's RSS feed from its current config and its known
+// episodes (oldest first).
+func generateFeed(p *podcast, wc watcherConfig, episodes []episode) ([]byte, error) {
+	feedURL := wc.urlFor(p.ShortName + "/feed.xml")
+
+	channel := rssChannel{
+		Title:          p.Name,
+		Link:           feedURL,
+		Description:    p.Description,
+		Language:       p.Language,
+		ItunesImage:    &itunesHref{Href: wc.urlFor(p.ShortName + "/art.jpg")},
+		ItunesExplicit: strconv.FormatBool(p.ItunesExplicit),
+		ItunesAuthor:   p.ItunesAuthor,
+		ItunesType:     p.ItunesType,
+		ItunesLanguage: p.Language,
+		PodcastGUID:    uuid.NewSHA1(podcastGUIDNamespace, []byte(feedURL)).String(),
+	}
+	if p.ItunesComplete {
+		channel.ItunesComplete = "yes"
+	}
+	if p.ItunesOwnerEmail != "" {
+		channel.ItunesOwner = &itunesOwner{Name: p.ItunesAuthor, Email: p.ItunesOwnerEmail}
+	}
+	if p.ItunesCategory != "" {
+		cat := &itunesCategory{Text: p.ItunesCategory}
+		if p.ItunesSubcategory != "" {
+			cat.Subcategory = &itunesCategory{Text: p.ItunesSubcategory}
+		}
+		channel.ItunesCategory = cat
+	}
+
+	for _, e := range episodes {
+		item := rssItem{
+			Title:       e.Title,
+			Description: e.Description,
+			PubDate:     e.PublishedAt.Format(time.RFC1123Z),
+			GUID:        e.YTVideoID,
+			Enclosure: enclosure{
+				URL:    wc.urlFor(e.MediaFilePath),
+				Length: strconv.FormatInt(e.MediaFileSize, 10),
+				Type:   enclosureMIMEType(wc.YTDLWriteExt),
+			},
+			ItunesDuration:    formatDuration(e.DurationSecs),
+			ItunesExplicit:    strconv.FormatBool(e.Explicit),
+			ItunesSeason:      e.Season,
+			ItunesEpisode:     e.EpisodeNum,
+			ItunesEpisodeType: e.EpisodeType,
+			Language:          e.Language,
+		}
+		if e.TranscriptPath != "" {
+			item.PodcastTranscript = &podcastTranscript{
+				URL:  wc.urlFor(e.TranscriptPath),
+				Type: "text/plain",
+			}
+		}
+		channel.Items = append(channel.Items, item)
+	}
+
+	feed := rssFeed{
+		Version:      "2.0",
+		XmlnsItunes:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		XmlnsPodcast: "https://podcastindex.org/namespace/1.0",
+		Channel:      channel,
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		return nil, fmt.Errorf("generate feed for %s: %w", p, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func enclosureMIMEType(ext string) string {
+	switch strings.ToLower(ext) {
+	case "m4a":
+		return "audio/x-m4a"
+	case "mp3":
+		return "audio/mpeg"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func formatDuration(secs int) string {
+	if secs <= 0 {
+		return ""
+	}
+	d := time.Duration(secs) * time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// probeDuration shells out to ffprobe to determine the duration, in whole
+// seconds, of the media file at path. It is called once per downloaded
+// episode so the feed can carry an accurate <itunes:duration>.
+func probeDuration(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	out, err := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	).Output()
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe %s: %w", path, err)
+	}
+	secs, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe %s: unexpected output %q: %w", path, out, err)
+	}
+	return int(secs), nil
+}
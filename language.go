@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+// detectLanguage guesses the BCP-47 language of v, trying progressively
+// less reliable signals: the Data API's own language metadata first, then a
+// heuristic over the title's Unicode scripts, and finally giving up (an
+// empty result means "unknown", which language_filter treats as a pass).
+func detectLanguage(v ytapi.Video) string {
+	if v.DefaultAudioLanguage != "" {
+		return v.DefaultAudioLanguage
+	}
+	if v.DefaultLanguage != "" {
+		return v.DefaultLanguage
+	}
+	return detectLanguageFromScript(v.Title)
+}
+
+// scriptLanguages maps a Unicode script predominantly used by one language
+// to that language's tag. It is necessarily approximate (e.g. Han is
+// shared by Chinese and Japanese) and is only a fallback for videos with no
+// API-reported language.
+var scriptLanguages = []struct {
+	script unicode.RangeTable
+	lang   string
+}{
+	{*unicode.Hiragana, "ja"},
+	{*unicode.Katakana, "ja"},
+	{*unicode.Hangul, "ko"},
+	{*unicode.Han, "zh"},
+	{*unicode.Arabic, "ar"},
+	{*unicode.Cyrillic, "ru"},
+	{*unicode.Thai, "th"},
+	{*unicode.Devanagari, "hi"},
+}
+
+func detectLanguageFromScript(title string) string {
+	for _, sl := range scriptLanguages {
+		for _, r := range title {
+			if unicode.Is(&sl.script, r) {
+				return sl.lang
+			}
+		}
+	}
+	return ""
+}
+
+// matchesLanguageFilter reports whether detected satisfies filter. An empty
+// filter or an undetected language always matches (we don't drop videos on
+// a guess we're not confident in). Otherwise the primary subtag must match,
+// so a filter of "en" matches a detected "en-US".
+func matchesLanguageFilter(detected, filter string) bool {
+	if filter == "" || detected == "" {
+		return true
+	}
+	return primarySubtag(detected) == primarySubtag(filter)
+}
+
+func primarySubtag(tag string) string {
+	if i := strings.IndexByte(tag, '-'); i != -1 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
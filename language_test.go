@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	cases := []struct {
+		name string
+		v    ytapi.Video
+		want string
+	}{
+		{"default audio language wins", ytapi.Video{DefaultAudioLanguage: "en-US", DefaultLanguage: "fr"}, "en-US"},
+		{"falls back to default language", ytapi.Video{DefaultLanguage: "fr"}, "fr"},
+		{"falls back to script heuristic", ytapi.Video{Title: "日本語のタイトル"}, "ja"},
+		{"unknown when no signal matches", ytapi.Video{Title: "Plain English Title"}, ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := detectLanguage(c.v); got != c.want {
+				t.Errorf("detectLanguage(%+v) = %q, want %q", c.v, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMatchesLanguageFilter(t *testing.T) {
+	cases := []struct {
+		detected, filter string
+		want             bool
+	}{
+		{"en-US", "en", true},
+		{"en", "en-US", true},
+		{"fr", "en", false},
+		{"", "en", true},
+		{"fr", "", true},
+	}
+	for _, c := range cases {
+		if got := matchesLanguageFilter(c.detected, c.filter); got != c.want {
+			t.Errorf("matchesLanguageFilter(%q, %q) = %v, want %v", c.detected, c.filter, got, c.want)
+		}
+	}
+}
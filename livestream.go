@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+// eligibleForIngest decides whether v should be queued for download right
+// now. It logs a structured event for every video it rejects, so livestream
+// and unlisted handling is auditable from the logs alone.
+func (p *podcast) eligibleForIngest(v ytapi.Video, now time.Time) bool {
+	if !v.WasLivestream() {
+		return true
+	}
+
+	if !p.IncludeLivestreams {
+		logEvent(p.ShortName, v.ID, "livestream-excluded", "title", v.Title)
+		return false
+	}
+	if v.IsLive() {
+		logEvent(p.ShortName, v.ID, "livestream-not-finished", "state", v.LiveBroadcastContent)
+		return false
+	}
+	if v.LiveStreamEndedAt.IsZero() {
+		// Ended but the API hasn't reported actualEndTime yet; try again
+		// next poll.
+		logEvent(p.ShortName, v.ID, "livestream-end-time-pending")
+		return false
+	}
+
+	minFinished := time.Duration(p.LivestreamMinFinishedMinutes) * time.Minute
+	if now.Sub(v.LiveStreamEndedAt) < minFinished {
+		logEvent(p.ShortName, v.ID, "livestream-cooling-down",
+			"ended_at", v.LiveStreamEndedAt.Format(time.RFC3339))
+		return false
+	}
+
+	logEvent(p.ShortName, v.ID, "livestream-ready")
+	return true
+}
+
+// recheckPrivacy re-fetches the privacy status of every episode already
+// known to p and, if drop_when_unlisted is set and one has flipped to
+// unlisted or private, removes its downloaded media and drops it from the
+// feed.
+func (w *watcher) recheckPrivacy(p podcast, wc watcherConfig) error {
+	if !p.DropWhenUnlisted {
+		return nil
+	}
+
+	episodes, err := p.loadEpisodes()
+	if err != nil || len(episodes) == 0 {
+		return err
+	}
+
+	ids := make([]string, len(episodes))
+	for i, e := range episodes {
+		ids[i] = e.YTVideoID
+	}
+	videos, err := w.yt.VideoDetails(context.Background(), ids)
+	if err != nil {
+		return err
+	}
+	statusByID := make(map[string]string, len(videos))
+	for _, v := range videos {
+		statusByID[v.ID] = v.PrivacyStatus
+	}
+
+	kept := episodes[:0]
+	changed := false
+	for _, e := range episodes {
+		status := statusByID[e.YTVideoID]
+		if status == "unlisted" || status == "private" {
+			logEvent(p.ShortName, e.YTVideoID, "dropped-"+status, "title", e.Title)
+			if e.MediaFilePath != "" {
+				if err := os.Remove(e.MediaFilePath); err != nil && !os.IsNotExist(err) {
+					log.Printf("%s: remove %s: %v", p.ShortName, e.MediaFilePath, err)
+				}
+			}
+			changed = true
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if !changed {
+		return nil
+	}
+	if err := p.saveEpisodes(kept); err != nil {
+		return err
+	}
+	return w.regenerateFeed(p, wc)
+}
+
+// logEvent writes a structured line for a livestream/unlisted state
+// transition: `key=value` pairs after the fixed podcast/video/event fields,
+// so logs stay greppable without pulling in a structured-logging library.
+func logEvent(podcastShortName, videoID, event string, kv ...string) {
+	line := fmt.Sprintf("event=%s podcast=%s video=%s", event, podcastShortName, videoID)
+	for i := 0; i+1 < len(kv); i += 2 {
+		line += fmt.Sprintf(" %s=%q", kv[i], kv[i+1])
+	}
+	log.Println(line)
+}
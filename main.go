@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+const (
+	downloadCmdName = "yt-dlp"
+
+	dataSubdirMetadata = "metadata"
+	dataSubdirMedia    = "media"
+)
+
+// websrv is the HTTP server that serves generated RSS feeds, podcast art,
+// and downloaded media. watcherConfig.urlFor does not consult it; it builds
+// URLs from serve_host and serve_port.
+var websrv *http.Server
+
+// mediaFileSystem returns the http.FileSystem backing the /media/ route. If
+// directory listings aren't wanted, it refuses to Open a directory so
+// http.FileServer falls through to a 404 instead of rendering an index.
+func mediaFileSystem(allowDirectoryListings bool) http.FileSystem {
+	dir := http.Dir(dataSubdirMedia)
+	if allowDirectoryListings {
+		return dir
+	}
+	return noListingFileSystem{dir}
+}
+
+type noListingFileSystem struct {
+	http.FileSystem
+}
+
+func (fs noListingFileSystem) Open(name string) (http.File, error) {
+	f, err := fs.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil && fi.IsDir() {
+		f.Close()
+		return nil, os.ErrNotExist
+	}
+	return f, nil
+}
+
+func main() {
+	configPath := flag.String("config", "config.json", "path to the config file")
+	devMode := flag.Bool("dev", false,
+		"watch the config file and hot-reload podcasts instead of requiring a restart")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatalln("config:", err)
+	}
+
+	yt, err := ytapi.NewGoogleClient(context.Background(), cfg.YTDataAPIKey, cfg.YTAPIQuotaDaily)
+	if err != nil {
+		log.Fatalln("ytapi:", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/quota", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(yt.QuotaSnapshot())
+	})
+	mux.Handle("/"+dataSubdirMedia+"/", http.StripPrefix("/"+dataSubdirMedia+"/",
+		http.FileServer(mediaFileSystem(cfg.ServeDirectoryListings))))
+	websrv = &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", cfg.ServeHost, cfg.ServePort),
+		Handler: mux,
+	}
+
+	mgr := newWatcherManager(mux, yt)
+	if err := mgr.sync(cfg); err != nil {
+		log.Fatalln("watchers:", err)
+	}
+
+	mux.HandleFunc("/admin/retry/", func(w http.ResponseWriter, r *http.Request) {
+		videoID := strings.TrimPrefix(r.URL.Path, "/admin/retry/")
+		found, err := mgr.forceRetry(videoID)
+		switch {
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case !found:
+			http.NotFound(w, r)
+		default:
+			fmt.Fprintf(w, "queued %s for retry\n", videoID)
+		}
+	})
+
+	if *devMode {
+		d, err := newDevSupervisor(*configPath, mgr)
+		if err != nil {
+			log.Fatalln("dev:", err)
+		}
+		go d.run()
+	}
+
+	log.Println("serving on", websrv.Addr)
+	if err := websrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalln(err)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+// Source is where a podcast's videos come from. It is a discriminated
+// union over the "type" field of a podcast's "source" config object, so
+// that the same watcher loop can ingest a whole channel, a single
+// playlist, a channel handle, or a standing search query.
+type Source interface {
+	// List returns the videos available from this source published since
+	// the given time.
+	List(ctx context.Context, yt ytapi.Client, since time.Time) ([]ytapi.Video, error)
+}
+
+// channelSource ingests every upload on a channel, identified by ID or by
+// name (see ytapi.Client.ResolveChannel).
+type channelSource struct {
+	Value string `json:"value"`
+
+	resolvedID string
+}
+
+func (s *channelSource) List(ctx context.Context, yt ytapi.Client, since time.Time) ([]ytapi.Video, error) {
+	if s.resolvedID == "" {
+		info, err := yt.ResolveChannel(ctx, s.Value)
+		if err != nil {
+			return nil, err
+		}
+		s.resolvedID = info.ID
+	}
+	return yt.RecentUploads(ctx, s.resolvedID, since)
+}
+
+// handleSource is identical to channelSource except it is always given an
+// @handle rather than a name/ID, which just reads better in config.json.
+type handleSource struct {
+	Value string `json:"value"`
+
+	resolvedID string
+}
+
+func (s *handleSource) List(ctx context.Context, yt ytapi.Client, since time.Time) ([]ytapi.Video, error) {
+	if s.resolvedID == "" {
+		info, err := yt.ResolveChannel(ctx, s.Value)
+		if err != nil {
+			return nil, err
+		}
+		s.resolvedID = info.ID
+	}
+	return yt.RecentUploads(ctx, s.resolvedID, since)
+}
+
+// playlistSource ingests the videos in a single playlist, in playlist
+// order, rather than an entire channel.
+type playlistSource struct {
+	ID string `json:"id"`
+}
+
+func (s *playlistSource) List(ctx context.Context, yt ytapi.Client, since time.Time) ([]ytapi.Video, error) {
+	return yt.PlaylistItems(ctx, s.ID, since)
+}
+
+// searchSource ingests whatever currently matches a standing search query,
+// optionally restricted to a single channel.
+type searchSource struct {
+	Query         string `json:"query"`
+	ChannelFilter string `json:"channel_filter"`
+}
+
+func (s *searchSource) List(ctx context.Context, yt ytapi.Client, since time.Time) ([]ytapi.Video, error) {
+	return yt.SearchVideos(ctx, s.Query, s.ChannelFilter, since)
+}
+
+// ------------------------------------------------------------
+
+// unmarshalSource decodes a podcast's "source" object, dispatching on its
+// "type" field.
+func unmarshalSource(raw json.RawMessage) (Source, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+
+	var src Source
+	switch head.Type {
+	case "channel":
+		src = new(channelSource)
+	case "handle":
+		src = new(handleSource)
+	case "playlist":
+		src = new(playlistSource)
+	case "search":
+		src = new(searchSource)
+	default:
+		return nil, fmt.Errorf("source: unknown \"type\" %q", head.Type)
+	}
+	if err := json.Unmarshal(raw, src); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frou/yt2pod/ytapi"
+)
+
+// watcher polls YouTube for one configured podcast, downloads any new
+// matching videos, and keeps that podcast's RSS feed up to date.
+type watcher struct {
+	// mu guards podcast and watcherConfig below, since rebuild can be
+	// called (via watcherManager.sync, from the dev-reload goroutine)
+	// concurrently with this watcher's own run loop reading them.
+	mu sync.RWMutex
+	podcast
+	watcherConfig
+
+	yt ytapi.Client
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func newWatcher(p podcast, wc watcherConfig, yt ytapi.Client) *watcher {
+	return &watcher{
+		podcast:       p,
+		watcherConfig: wc,
+		yt:            yt,
+		stopCh:        make(chan struct{}),
+		doneCh:        make(chan struct{}),
+	}
+}
+
+// snapshot returns a consistent copy of w's current podcast config and
+// watcherConfig. Everything that acts on them (poll/pollOnce/ingest/
+// regenerateFeed/run's interval check) takes one snapshot per operation and
+// passes it down, rather than reading w.podcast/w.watcherConfig directly,
+// so a concurrent rebuild can't be observed half-applied.
+func (w *watcher) snapshot() (podcast, watcherConfig) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.podcast, w.watcherConfig
+}
+
+// run polls on a ticker until stop is called. It is intended to be launched
+// in its own goroutine. The ticker's interval is re-read after every tick,
+// so a rebuild that changes check_interval_minutes takes effect from the
+// following cycle onward.
+func (w *watcher) run() {
+	defer close(w.doneCh)
+
+	_, wc := w.snapshot()
+	interval := time.Duration(wc.CheckIntervalMinutes) * time.Minute
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	w.poll()
+	for {
+		select {
+		case <-t.C:
+			w.poll()
+			_, wc := w.snapshot()
+			if next := time.Duration(wc.CheckIntervalMinutes) * time.Minute; next != interval {
+				interval = next
+				t.Reset(interval)
+			}
+		case <-w.stopCh:
+			return
+		}
+	}
+}
+
+// poll checks for new uploads, downloads any that pass the title filter, and
+// regenerates the feed if anything changed.
+func (w *watcher) poll() {
+	if err := w.pollOnce(); err != nil {
+		p, _ := w.snapshot()
+		log.Printf("%s: poll: %v", p.ShortName, err)
+	}
+}
+
+// pollOnce does a single round of work for w: it checks p.Source for videos
+// published since p.Epoch, downloads any that pass p.TitleFilter, and
+// regenerates the feed if anything changed. It works from a single snapshot
+// of w's config taken at the start, so a concurrent rebuild doesn't change
+// the rules mid-poll.
+func (w *watcher) pollOnce() error {
+	p, wc := w.snapshot()
+
+	if w.yt.QuotaNearLimit() {
+		log.Printf("%s: skipping poll, yt_api_quota_daily nearly exhausted (%d used)",
+			p.ShortName, w.yt.QuotaUsed())
+		return nil
+	}
+
+	if err := w.recheckPrivacy(p, wc); err != nil {
+		log.Printf("%s: recheck privacy: %v", p.ShortName, err)
+	}
+
+	videos, err := p.Source.List(context.Background(), w.yt, p.Epoch)
+	if err != nil {
+		return err
+	}
+
+	existing, err := p.loadEpisodes()
+	if err != nil {
+		return err
+	}
+	alreadyIngested := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		alreadyIngested[e.YTVideoID] = true
+	}
+
+	var matched []ytapi.Video
+	now := time.Now()
+	for _, v := range videos {
+		if alreadyIngested[v.ID] {
+			continue
+		}
+		if !p.TitleFilter.MatchString(v.Title) {
+			continue
+		}
+		if !p.eligibleForIngest(v, now) {
+			continue
+		}
+		if !matchesLanguageFilter(detectLanguage(v), p.LanguageFilterStr) {
+			logEvent(p.ShortName, v.ID, "language-filtered", "title", v.Title)
+			continue
+		}
+		matched = append(matched, v)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	changed := false
+	for _, v := range matched {
+		added, err := w.ingest(p, wc, v)
+		if err != nil {
+			log.Printf("%s: ingest %s: %v", p.ShortName, v.ID, err)
+			continue
+		}
+		changed = changed || added
+	}
+	if !changed {
+		return nil
+	}
+	return w.regenerateFeed(p, wc)
+}
+
+// ingest downloads v (honouring the retry/blocklist policy in download.go)
+// and, on success, records it as a new episode. It reports whether a new
+// episode was added.
+func (w *watcher) ingest(p podcast, wc watcherConfig, v ytapi.Video) (bool, error) {
+	ok, err := w.downloadVideo(p, wc, videoToDownload{
+		ID:          v.ID,
+		Title:       v.Title,
+		Description: v.Description,
+		PublishedAt: v.PublishedAt,
+	})
+	if err != nil || !ok {
+		return false, err
+	}
+
+	mediaPath := filepath.Join(dataSubdirMedia, v.ID+"."+wc.YTDLWriteExt)
+	duration, err := probeDuration(mediaPath)
+	if err != nil {
+		log.Printf("%s: probe duration for %s: %v", p.ShortName, v.ID, err)
+	}
+	size := int64(0)
+	if fi, err := os.Stat(mediaPath); err == nil {
+		size = fi.Size()
+	}
+
+	explicit := p.ItunesExplicit
+	if p.ExplicitFilter != nil && (p.ExplicitFilter.MatchString(v.Title) || p.ExplicitFilter.MatchString(v.Description)) {
+		explicit = true
+	}
+
+	episodeType := "full"
+	switch {
+	case p.TrailerFilter != nil && (p.TrailerFilter.MatchString(v.Title) || p.TrailerFilter.MatchString(v.Description)):
+		episodeType = "trailer"
+	case p.BonusFilter != nil && (p.BonusFilter.MatchString(v.Title) || p.BonusFilter.MatchString(v.Description)):
+		episodeType = "bonus"
+	}
+	season, episodeNum := seasonAndEpisodeNumber(p.SeasonEpisodeFilter, v.Title)
+
+	episodes, err := p.loadEpisodes()
+	if err != nil {
+		return false, err
+	}
+	episodes = append(episodes, episode{
+		YTVideoID:      v.ID,
+		Title:          v.Title,
+		Description:    v.Description,
+		PublishedAt:    v.PublishedAt,
+		MediaFilePath:  mediaPath,
+		MediaFileSize:  size,
+		DurationSecs:   duration,
+		Season:         season,
+		EpisodeNum:     episodeNum,
+		EpisodeType:    episodeType,
+		Explicit:       explicit,
+		Language:       detectLanguage(v),
+		TranscriptPath: findTranscriptPath(mediaPath),
+	})
+	if err := p.saveEpisodes(episodes); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// transcriptExtensions are the subtitle/transcript file extensions that may
+// be sitting alongside a downloaded video's audio (e.g. left by a prior
+// yt-dlp run configured to fetch subtitles).
+var transcriptExtensions = []string{"vtt", "srt"}
+
+// findTranscriptPath looks for a transcript file alongside mediaPath (same
+// base name, a subtitle extension), returning "" if none exists.
+func findTranscriptPath(mediaPath string) string {
+	base := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath))
+	for _, ext := range transcriptExtensions {
+		candidate := base + "." + ext
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// regenerateFeed rewrites the podcast's RSS feed on disk from its currently
+// known episodes.
+func (w *watcher) regenerateFeed(p podcast, wc watcherConfig) error {
+	episodes, err := p.loadEpisodes()
+	if err != nil {
+		return err
+	}
+	buf, err := generateFeed(&p, wc, episodes)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p.feedPath(), buf, 0644)
+}
+
+// stop signals the watcher's run loop to exit and waits for it to do so. It
+// is safe to call at most once per watcher.
+func (w *watcher) stop() {
+	close(w.stopCh)
+	<-w.doneCh
+}
+
+// rebuild swaps in a new podcast definition (e.g. an edited title_filter or
+// epoch) and watcherConfig (e.g. an edited ytdl_fmt_selector or
+// check_interval_minutes) without restarting the watcher's run loop or
+// losing its in-flight state. It is safe to call concurrently with the
+// watcher's own run loop.
+func (w *watcher) rebuild(p podcast, wc watcherConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.podcast = p
+	w.watcherConfig = wc
+}
+
+// ------------------------------------------------------------
+
+// watcherManager owns the set of currently-running watchers, keyed by
+// podcast short name, and the HTTP routes that serve their output.
+type watcherManager struct {
+	mux *http.ServeMux
+	yt  ytapi.Client
+
+	mu               sync.Mutex
+	watchers         map[string]*watcher
+	routesRegistered map[string]bool
+	wc               watcherConfig
+}
+
+func newWatcherManager(mux *http.ServeMux, yt ytapi.Client) *watcherManager {
+	return &watcherManager{
+		mux:              mux,
+		yt:               yt,
+		watchers:         make(map[string]*watcher),
+		routesRegistered: make(map[string]bool),
+	}
+}
+
+// sync reconciles the running watchers against cfg: podcasts that are new
+// are started, podcasts that have been removed are stopped (their feed and
+// art files on disk are left alone), and podcasts that already exist are
+// rebuilt in place so any in-flight download is not interrupted.
+func (m *watcherManager) sync(cfg *config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.wc = cfg.watcherConfig
+
+	seen := make(map[string]bool, len(cfg.Podcasts))
+	for _, p := range cfg.Podcasts {
+		seen[p.ShortName] = true
+
+		if w, ok := m.watchers[p.ShortName]; ok {
+			w.rebuild(p, m.wc)
+			continue
+		}
+
+		w := newWatcher(p, m.wc, m.yt)
+		m.watchers[p.ShortName] = w
+		m.registerRoutes(w)
+		go w.run()
+	}
+
+	for name, w := range m.watchers {
+		if seen[name] {
+			continue
+		}
+		w.stop()
+		delete(m.watchers, name)
+	}
+
+	return nil
+}
+
+// forceRetry clears any persisted failure state for videoID, including a
+// permanent-failure marking, so the next poll of whichever podcast knows
+// about it will attempt the download again. It reports whether videoID was
+// found in any watcher's download state.
+func (m *watcherManager) forceRetry(videoID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, w := range m.watchers {
+		p, _ := w.snapshot()
+		states, err := p.loadDownloadStates()
+		if err != nil {
+			return false, err
+		}
+		if _, ok := states[videoID]; !ok {
+			continue
+		}
+		delete(states, videoID)
+		if err := p.saveDownloadStates(states); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// registerRoutes wires up w's feed and art routes. http.ServeMux has no way
+// to deregister a pattern, so a podcast that is removed and later re-added
+// under the same short_name must not register its routes a second time
+// (mux.HandleFunc panics on a duplicate pattern) — registerRoutes tracks
+// that in m.routesRegistered and skips the call when it's already done.
+// This is safe because both handlers below only depend on w.ShortName,
+// which is unchanged by a stop/restart of the same podcast.
+func (m *watcherManager) registerRoutes(w *watcher) {
+	if m.routesRegistered[w.ShortName] {
+		return
+	}
+	m.routesRegistered[w.ShortName] = true
+
+	m.mux.HandleFunc("/"+w.ShortName+"/feed.xml", func(rw http.ResponseWriter, r *http.Request) {
+		p, _ := w.snapshot()
+		http.ServeFile(rw, r, p.feedPath())
+	})
+	m.mux.HandleFunc("/"+w.ShortName+"/art.jpg", func(rw http.ResponseWriter, r *http.Request) {
+		p, _ := w.snapshot()
+		http.ServeFile(rw, r, p.artPath())
+	})
+}
@@ -0,0 +1,127 @@
+package ytapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// backoffThreshold is the fraction of the daily quota at which callers
+// should start backing off rather than wait to be hard-rejected by Google.
+const backoffThreshold = 0.9
+
+// quotaResetLocation is where YouTube Data API quota actually resets
+// (midnight Pacific Time), so usedTotal tracks the same "daily" window
+// Google enforces rather than drifting out of sync with it.
+var quotaResetLocation = func() *time.Location {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		// tzdata isn't available in every build environment (e.g. a
+		// minimal container); fall back to UTC rather than fail to start.
+		return time.UTC
+	}
+	return loc
+}()
+
+// quotaMeter tracks a running estimate of YouTube Data API quota units
+// spent, broken down by method, so the watcher can back off before Google
+// starts returning 403s instead of after. Usage resets every time the
+// rolling daily window (midnight Pacific) rolls over.
+type quotaMeter struct {
+	dailyLimit int // 0 means untracked/unlimited
+
+	mu        sync.Mutex
+	usedTotal int
+	usedByAPI map[string]int
+	resetAt   time.Time
+}
+
+func newQuotaMeter(dailyLimit int) *quotaMeter {
+	return &quotaMeter{
+		dailyLimit: dailyLimit,
+		usedByAPI:  make(map[string]int),
+		resetAt:    nextQuotaReset(time.Now()),
+	}
+}
+
+// nextQuotaReset returns the next midnight Pacific strictly after t.
+func nextQuotaReset(t time.Time) time.Time {
+	t = t.In(quotaResetLocation)
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, quotaResetLocation)
+}
+
+// resetIfDue zeroes usage once the current daily window has passed. Callers
+// must hold q.mu.
+func (q *quotaMeter) resetIfDue(now time.Time) {
+	if now.Before(q.resetAt) {
+		return
+	}
+	q.usedTotal = 0
+	q.usedByAPI = make(map[string]int)
+	q.resetAt = nextQuotaReset(now)
+}
+
+// spend records that method is about to be called at the given quota cost,
+// and refuses the call outright once the daily limit has been reached.
+func (q *quotaMeter) spend(method string, units int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resetIfDue(time.Now())
+
+	if q.dailyLimit > 0 && q.usedTotal+units > q.dailyLimit {
+		return fmt.Errorf("ytapi: refusing %s: would exceed daily quota of %d units (%d used)",
+			method, q.dailyLimit, q.usedTotal)
+	}
+
+	q.usedTotal += units
+	q.usedByAPI[method] += units
+	return nil
+}
+
+// nearLimit reports whether usage has crossed backoffThreshold of the daily
+// limit. Callers use this to slow their polling rate pre-emptively; it
+// always reports false when no daily limit is configured.
+func (q *quotaMeter) nearLimit() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resetIfDue(time.Now())
+
+	if q.dailyLimit == 0 {
+		return false
+	}
+	return float64(q.usedTotal) >= float64(q.dailyLimit)*backoffThreshold
+}
+
+func (q *quotaMeter) used() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resetIfDue(time.Now())
+	return q.usedTotal
+}
+
+// Snapshot is a point-in-time view of quota usage, suitable for marshalling
+// to JSON for the /debug/quota endpoint.
+type Snapshot struct {
+	DailyLimit int            `json:"daily_limit"`
+	UsedTotal  int            `json:"used_total"`
+	UsedByAPI  map[string]int `json:"used_by_api"`
+	ResetAt    time.Time      `json:"reset_at"`
+}
+
+func (q *quotaMeter) Snapshot() Snapshot {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.resetIfDue(time.Now())
+
+	byAPI := make(map[string]int, len(q.usedByAPI))
+	for k, v := range q.usedByAPI {
+		byAPI[k] = v
+	}
+	return Snapshot{
+		DailyLimit: q.dailyLimit,
+		UsedTotal:  q.usedTotal,
+		UsedByAPI:  byAPI,
+		ResetAt:    q.resetAt,
+	}
+}
@@ -0,0 +1,74 @@
+package ytapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuotaMeterSpendRefusesOverLimit(t *testing.T) {
+	q := newQuotaMeter(100)
+
+	if err := q.spend("videos.list", 60); err != nil {
+		t.Fatalf("spend within limit: %v", err)
+	}
+	if err := q.spend("videos.list", 60); err == nil {
+		t.Fatal("spend over limit: want error, got nil")
+	}
+	if got, want := q.used(), 60; got != want {
+		t.Errorf("used() = %d, want %d (refused spend shouldn't be counted)", got, want)
+	}
+}
+
+func TestQuotaMeterSpendUnlimited(t *testing.T) {
+	q := newQuotaMeter(0)
+
+	if err := q.spend("search.list", 1_000_000); err != nil {
+		t.Fatalf("spend with dailyLimit 0: %v", err)
+	}
+}
+
+func TestQuotaMeterNearLimit(t *testing.T) {
+	q := newQuotaMeter(100)
+
+	if q.nearLimit() {
+		t.Fatal("nearLimit() = true before any spend")
+	}
+	if err := q.spend("videos.list", 90); err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+	if !q.nearLimit() {
+		t.Fatal("nearLimit() = false at 90% of daily limit, want true")
+	}
+}
+
+func TestQuotaMeterResetsOnDayBoundary(t *testing.T) {
+	q := newQuotaMeter(100)
+	if err := q.spend("videos.list", 90); err != nil {
+		t.Fatalf("spend: %v", err)
+	}
+
+	// Simulate the day boundary having already passed.
+	q.resetAt = time.Now().Add(-time.Minute)
+
+	if got, want := q.used(), 0; got != want {
+		t.Errorf("used() after boundary = %d, want %d", got, want)
+	}
+	if err := q.spend("videos.list", 50); err != nil {
+		t.Errorf("spend after reset should have headroom again: %v", err)
+	}
+}
+
+func TestQuotaMeterSnapshotByAPI(t *testing.T) {
+	q := newQuotaMeter(0)
+	q.spend("search.list", 100)
+	q.spend("videos.list", 1)
+	q.spend("videos.list", 1)
+
+	snap := q.Snapshot()
+	if got, want := snap.UsedTotal, 102; got != want {
+		t.Errorf("UsedTotal = %d, want %d", got, want)
+	}
+	if got, want := snap.UsedByAPI["videos.list"], 2; got != want {
+		t.Errorf("UsedByAPI[videos.list] = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,324 @@
+// Package ytapi centralizes every call this program makes to the YouTube
+// Data API behind a small typed Client interface, so the watcher never
+// touches google.golang.org/api/youtube/v3 directly. That gives us one place
+// to batch requests, account for quota cost, and (eventually) swap in a
+// non-Google backend such as Invidious or Piped for tests.
+package ytapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/youtube/v3"
+)
+
+// videoDetailBatchSize is the most video IDs the Data API allows per
+// videos.list call.
+const videoDetailBatchSize = 50
+
+// ChannelInfo is the subset of a channels.list result the watcher needs.
+type ChannelInfo struct {
+	ID           string
+	ReadableName string
+}
+
+// Video is the subset of a videos.list result the watcher needs.
+type Video struct {
+	ID          string
+	Title       string
+	Description string
+	PublishedAt time.Time
+
+	// LiveBroadcastContent is "none" for an ordinary upload, or "live" /
+	// "upcoming" for a stream that is currently live or scheduled.
+	LiveBroadcastContent string
+	// LiveStreamEndedAt is the stream's actualEndTime, or the zero Time if
+	// it was never a livestream or hasn't ended yet.
+	LiveStreamEndedAt time.Time
+
+	// PrivacyStatus is "public", "unlisted", or "private".
+	PrivacyStatus string
+
+	// DefaultAudioLanguage and DefaultLanguage are BCP-47 tags as reported
+	// by the Data API, if the uploader set them. Either may be empty.
+	DefaultAudioLanguage string
+	DefaultLanguage      string
+}
+
+// IsLive reports whether v is a stream that is currently live or scheduled,
+// as opposed to an ordinary upload or a stream that has since ended.
+func (v Video) IsLive() bool {
+	return v.LiveBroadcastContent == "live" || v.LiveBroadcastContent == "upcoming"
+}
+
+// WasLivestream reports whether v was ever a livestream, live or otherwise.
+// A stream that has ended reports LiveBroadcastContent "none", so it is
+// recognised by its LiveStreamEndedAt instead.
+func (v Video) WasLivestream() bool {
+	return v.IsLive() || !v.LiveStreamEndedAt.IsZero()
+}
+
+// Client is everything the watcher needs from the YouTube Data API. It is
+// an interface so tests (and a future Invidious/Piped fallback) can supply
+// a non-Google implementation.
+type Client interface {
+	// ResolveChannel turns a channel ID, a legacy channel name, or an
+	// @handle into a ChannelInfo.
+	ResolveChannel(ctx context.Context, handleOrID string) (ChannelInfo, error)
+
+	// RecentUploads returns videos uploaded to channelID since the given
+	// time, newest first.
+	RecentUploads(ctx context.Context, channelID string, since time.Time) ([]Video, error)
+
+	// VideoDetails looks up full details for a set of video IDs, batching
+	// requests internally to stay within the API's per-call limit.
+	VideoDetails(ctx context.Context, ids []string) ([]Video, error)
+
+	// PlaylistItems returns the videos in playlistID published since the
+	// given time, in playlist order.
+	PlaylistItems(ctx context.Context, playlistID string, since time.Time) ([]Video, error)
+
+	// SearchVideos returns videos matching query, published since the
+	// given time, optionally restricted to a single channel.
+	SearchVideos(ctx context.Context, query, channelFilter string, since time.Time) ([]Video, error)
+
+	// QuotaUsed returns the running estimate of quota units spent so far.
+	QuotaUsed() int
+
+	// QuotaSnapshot returns a breakdown of quota usage by method, suitable
+	// for serving from a /debug/quota endpoint.
+	QuotaSnapshot() Snapshot
+
+	// QuotaNearLimit reports whether usage has crossed the point at which
+	// callers should back off rather than wait to be hard-rejected.
+	QuotaNearLimit() bool
+}
+
+// googleClient is the production Client backed by the real YouTube Data
+// API.
+type googleClient struct {
+	svc   *youtube.Service
+	quota *quotaMeter
+}
+
+// NewGoogleClient builds a Client backed by the real YouTube Data API,
+// accounting quota usage against dailyLimit (0 means unlimited).
+func NewGoogleClient(ctx context.Context, apiKey string, dailyLimit int) (Client, error) {
+	svc, err := youtube.NewService(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("ytapi: %w", err)
+	}
+	return &googleClient{
+		svc:   svc,
+		quota: newQuotaMeter(dailyLimit),
+	}, nil
+}
+
+func (c *googleClient) ResolveChannel(ctx context.Context, handleOrID string) (ChannelInfo, error) {
+	if err := c.quota.spend("channels.list", 1); err != nil {
+		return ChannelInfo{}, err
+	}
+
+	call := c.svc.Channels.List("snippet").Context(ctx)
+	if len(handleOrID) > 0 && handleOrID[0] == '@' {
+		// v0.25.0 of the Data API client predates handle-based lookup
+		// (channels.list's forHandle parameter); fall back to the legacy
+		// username lookup, which covers most existing @handles since they
+		// were originally minted from a channel's custom URL/username.
+		call = call.ForUsername(strings.TrimPrefix(handleOrID, "@"))
+	} else {
+		call = call.Id(handleOrID)
+	}
+
+	resp, err := call.Do()
+	if err != nil {
+		return ChannelInfo{}, fmt.Errorf("ytapi: resolve channel %q: %w", handleOrID, err)
+	}
+	if len(resp.Items) == 0 && !strings.HasPrefix(handleOrID, "@") {
+		// Not a channel ID; try it as a legacy channel name.
+		if err := c.quota.spend("channels.list", 1); err != nil {
+			return ChannelInfo{}, err
+		}
+		resp, err = c.svc.Channels.List("snippet").Context(ctx).ForUsername(handleOrID).Do()
+		if err != nil {
+			return ChannelInfo{}, fmt.Errorf("ytapi: resolve channel %q: %w", handleOrID, err)
+		}
+	}
+	if len(resp.Items) == 0 {
+		return ChannelInfo{}, fmt.Errorf("ytapi: no such channel %q", handleOrID)
+	}
+	item := resp.Items[0]
+	return ChannelInfo{ID: item.Id, ReadableName: item.Snippet.Title}, nil
+}
+
+func (c *googleClient) RecentUploads(ctx context.Context, channelID string, since time.Time) ([]Video, error) {
+	if err := c.quota.spend("search.list", 100); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.svc.Search.List("id").
+		Context(ctx).
+		ChannelId(channelID).
+		Order("date").
+		Type("video").
+		PublishedAfter(since.Format(time.RFC3339)).
+		MaxResults(50).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("ytapi: recent uploads for %s: %w", channelID, err)
+	}
+
+	ids := make([]string, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		ids = append(ids, item.Id.VideoId)
+	}
+	return c.VideoDetails(ctx, ids)
+}
+
+func (c *googleClient) VideoDetails(ctx context.Context, ids []string) ([]Video, error) {
+	var out []Video
+	for i := 0; i < len(ids); i += videoDetailBatchSize {
+		end := i + videoDetailBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch, err := c.videoDetailsBatch(ctx, ids[i:end])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, batch...)
+	}
+	return out, nil
+}
+
+func (c *googleClient) videoDetailsBatch(ctx context.Context, ids []string) ([]Video, error) {
+	if err := c.quota.spend("videos.list", 1); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.svc.Videos.List("snippet,liveStreamingDetails,status").
+		Context(ctx).
+		Id(strings.Join(ids, ",")).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("ytapi: video details: %w", err)
+	}
+
+	out := make([]Video, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		published, err := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ytapi: video %s: %w", item.Id, err)
+		}
+
+		v := Video{
+			ID:                   item.Id,
+			Title:                item.Snippet.Title,
+			Description:          item.Snippet.Description,
+			PublishedAt:          published,
+			LiveBroadcastContent: item.Snippet.LiveBroadcastContent,
+			DefaultAudioLanguage: item.Snippet.DefaultAudioLanguage,
+			DefaultLanguage:      item.Snippet.DefaultLanguage,
+		}
+		if item.Status != nil {
+			v.PrivacyStatus = item.Status.PrivacyStatus
+		}
+		if d := item.LiveStreamingDetails; d != nil && d.ActualEndTime != "" {
+			ended, err := time.Parse(time.RFC3339, d.ActualEndTime)
+			if err != nil {
+				return nil, fmt.Errorf("ytapi: video %s: %w", item.Id, err)
+			}
+			v.LiveStreamEndedAt = ended
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (c *googleClient) PlaylistItems(ctx context.Context, playlistID string, since time.Time) ([]Video, error) {
+	var ids []string
+	pageToken := ""
+	for {
+		if err := c.quota.spend("playlistItems.list", 1); err != nil {
+			return nil, err
+		}
+		call := c.svc.PlaylistItems.List("contentDetails").
+			Context(ctx).
+			PlaylistId(playlistID).
+			MaxResults(50)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("ytapi: playlist items for %s: %w", playlistID, err)
+		}
+		for _, item := range resp.Items {
+			ids = append(ids, item.ContentDetails.VideoId)
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	videos, err := c.VideoDetails(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return filterSince(videos, since), nil
+}
+
+func (c *googleClient) SearchVideos(ctx context.Context, query, channelFilter string, since time.Time) ([]Video, error) {
+	if err := c.quota.spend("search.list", 100); err != nil {
+		return nil, err
+	}
+
+	call := c.svc.Search.List("id").
+		Context(ctx).
+		Q(query).
+		Order("date").
+		Type("video").
+		PublishedAfter(since.Format(time.RFC3339)).
+		MaxResults(50)
+	if channelFilter != "" {
+		call = call.ChannelId(channelFilter)
+	}
+	resp, err := call.Do()
+	if err != nil {
+		return nil, fmt.Errorf("ytapi: search %q: %w", query, err)
+	}
+
+	ids := make([]string, 0, len(resp.Items))
+	for _, item := range resp.Items {
+		ids = append(ids, item.Id.VideoId)
+	}
+	return c.VideoDetails(ctx, ids)
+}
+
+// filterSince drops videos published before since. Unlike search.list,
+// playlistItems.list has no server-side "published after" filter.
+func filterSince(videos []Video, since time.Time) []Video {
+	out := videos[:0]
+	for _, v := range videos {
+		if !v.PublishedAt.Before(since) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (c *googleClient) QuotaUsed() int {
+	return c.quota.used()
+}
+
+func (c *googleClient) QuotaSnapshot() Snapshot {
+	return c.quota.Snapshot()
+}
+
+func (c *googleClient) QuotaNearLimit() bool {
+	return c.quota.nearLimit()
+}